@@ -0,0 +1,49 @@
+package pantilthat
+
+import "github.com/go-daq/smbus"
+
+// I2CBus abstracts the handful of SMBus operations PanTiltHat needs,
+// decoupling it from github.com/go-daq/smbus so callers can supply an
+// alternate backend (periph.io, a fake for tests, a HAT on a non-default
+// bus number) via PanTiltHatParams.Bus.
+type I2CBus interface {
+	ReadWord(addr, command uint8) (uint16, error)
+	WriteWord(addr, command uint8, value uint16) error
+	ReadReg(addr, command uint8) (uint8, error)
+	WriteReg(addr, command, value uint8) error
+	// WriteBlock writes data to consecutive registers starting at
+	// startReg, as one logical transfer. Used for LED data (ws2812.go),
+	// where it lets a backend that supports SMBus block writes send a
+	// whole chunk in a single transaction instead of one byte at a time.
+	WriteBlock(addr, startReg uint8, data []byte) error
+	Close() error
+}
+
+// smbusBus adapts *smbus.Conn to I2CBus.
+type smbusBus struct {
+	conn *smbus.Conn
+}
+
+func (b *smbusBus) ReadWord(addr, command uint8) (uint16, error) {
+	return b.conn.ReadWord(addr, command)
+}
+
+func (b *smbusBus) WriteWord(addr, command uint8, value uint16) error {
+	return b.conn.WriteWord(addr, command, value)
+}
+
+func (b *smbusBus) ReadReg(addr, command uint8) (uint8, error) {
+	return b.conn.ReadReg(addr, command)
+}
+
+func (b *smbusBus) WriteReg(addr, command, value uint8) error {
+	return b.conn.WriteReg(addr, command, value)
+}
+
+func (b *smbusBus) WriteBlock(addr, startReg uint8, data []byte) error {
+	return b.conn.WriteBlockData(addr, startReg, data)
+}
+
+func (b *smbusBus) Close() error {
+	return b.conn.Close()
+}
@@ -0,0 +1,112 @@
+package pantilthat
+
+import "sync"
+
+// fakeI2CBus is an in-memory I2CBus, letting the timing- and concurrency-
+// sensitive logic in motion.go, idle.go and i2c_retry.go run under `go
+// test` (and `go test -race`) without real hardware.
+type fakeI2CBus struct {
+	mu              sync.Mutex
+	words           map[uint8]uint16
+	regs            map[uint8]uint8
+	writeWordCalls  int
+	writeBlockCalls int
+	closed          bool
+}
+
+func newFakeI2CBus() *fakeI2CBus {
+	return &fakeI2CBus{
+		words: make(map[uint8]uint16),
+		regs:  make(map[uint8]uint8),
+	}
+}
+
+func (b *fakeI2CBus) ReadWord(addr, command uint8) (uint16, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.words[command], nil
+}
+
+func (b *fakeI2CBus) WriteWord(addr, command uint8, value uint16) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.words[command] = value
+	b.writeWordCalls++
+	return nil
+}
+
+func (b *fakeI2CBus) ReadReg(addr, command uint8) (uint8, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.regs[command], nil
+}
+
+func (b *fakeI2CBus) WriteReg(addr, command, value uint8) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.regs[command] = value
+	return nil
+}
+
+func (b *fakeI2CBus) WriteBlock(addr, startReg uint8, data []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, v := range data {
+		b.regs[startReg+uint8(i)] = v
+	}
+	b.writeBlockCalls++
+	return nil
+}
+
+func (b *fakeI2CBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	return nil
+}
+
+func (b *fakeI2CBus) reg(command uint8) uint8 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.regs[command]
+}
+
+func (b *fakeI2CBus) writeWordCallCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.writeWordCalls
+}
+
+func (b *fakeI2CBus) writeBlockCallCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.writeBlockCalls
+}
+
+func (b *fakeI2CBus) setWord(command uint8, value uint16) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.words[command] = value
+}
+
+// newTestHat builds a PanTiltHat against a fakeI2CBus pre-seeded with both
+// servos parked at their centre angle, so GetAngle doesn't error out
+// before a test ever calls SetAngle.
+func newTestHat(configure func(*PanTiltHatParams)) (*PanTiltHat, *fakeI2CBus) {
+	bus := newFakeI2CBus()
+
+	params := PanTiltHatParams{Bus: bus}
+	if configure != nil {
+		configure(&params)
+	}
+
+	hat, err := MakePanTiltHat(&params)
+	if err != nil {
+		panic(err) // unreachable: fakeI2CBus never fails to open.
+	}
+
+	bus.setWord(reg_servo1, (hat.params.Servo1Min+hat.params.Servo1Max)/2)
+	bus.setWord(reg_servo2, (hat.params.Servo2Min+hat.params.Servo2Max)/2)
+
+	return hat, bus
+}
@@ -0,0 +1,51 @@
+package pantilthat
+
+import (
+	"os"
+	"time"
+)
+
+// SetI2CRetries changes how many times a transient SMBus error is retried
+// and how long to sleep between attempts.
+func (hat *PanTiltHat) SetI2CRetries(n int, delay time.Duration) {
+	hat.i2cRetryMu.Lock()
+	defer hat.i2cRetryMu.Unlock()
+	hat.i2cRetries = n
+	hat.i2cRetryTime = delay
+}
+
+func (hat *PanTiltHat) getI2CRetries() (int, time.Duration) {
+	hat.i2cRetryMu.Lock()
+	defer hat.i2cRetryMu.Unlock()
+	return hat.i2cRetries, hat.i2cRetryTime
+}
+
+// withRetry runs op, retrying up to hat.i2cRetries times with
+// hat.i2cRetryTime between attempts when it returns a transient error. A
+// single flaky EIO from the kernel driver shouldn't crash a long-running
+// control loop.
+func (hat *PanTiltHat) withRetry(op func() error) error {
+	retries, retryTime := hat.getI2CRetries()
+
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		err = op()
+		if err == nil {
+			return nil
+		}
+		if !isTransientI2CError(err) {
+			return err
+		}
+		if attempt < retries {
+			time.Sleep(retryTime)
+		}
+	}
+	return err
+}
+
+// isTransientI2CError reports whether err is worth retrying. Permission
+// and missing-device errors are persistent, so retrying them just delays
+// the inevitable.
+func isTransientI2CError(err error) bool {
+	return !os.IsPermission(err) && !os.IsNotExist(err)
+}
@@ -0,0 +1,65 @@
+package pantilthat
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWithRetryRetriesTransientErrors(t *testing.T) {
+	hat := &PanTiltHat{}
+	hat.SetI2CRetries(3, time.Millisecond)
+
+	attempts := 0
+	err := hat.withRetry(func() error {
+		attempts++
+		return errors.New("transient EIO")
+	})
+
+	if err == nil {
+		t.Fatal("expected an error after exhausting retries")
+	}
+	if attempts != 4 { // 1 initial attempt + 3 retries
+		t.Errorf("attempts = %d, want 4", attempts)
+	}
+}
+
+func TestWithRetrySucceedsPartway(t *testing.T) {
+	hat := &PanTiltHat{}
+	hat.SetI2CRetries(5, time.Millisecond)
+
+	attempts := 0
+	err := hat.withRetry(func() error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("transient EIO")
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("withRetry: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestWithRetryDoesNotRetryPermissionErrors(t *testing.T) {
+	hat := &PanTiltHat{}
+	hat.SetI2CRetries(5, time.Millisecond)
+
+	attempts := 0
+	err := hat.withRetry(func() error {
+		attempts++
+		return os.ErrPermission
+	})
+
+	if err == nil {
+		t.Fatal("expected a permission error")
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1 (permission errors shouldn't be retried)", attempts)
+	}
+}
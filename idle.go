@@ -0,0 +1,55 @@
+package pantilthat
+
+import "time"
+
+// idlePollInterval is how often the idle monitor checks for expired
+// servos; it mirrors the ticker + quit-channel poll loop used by embd's
+// MPU6050 driver.
+const idlePollInterval = 100 * time.Millisecond
+
+// SetIdleTimeout changes how long a servo may sit unused before it is
+// automatically disabled to stop it buzzing and drawing current. A zero
+// duration disables idle handling entirely.
+func (hat *PanTiltHat) SetIdleTimeout(d time.Duration) {
+	hat.idleMu.Lock()
+	defer hat.idleMu.Unlock()
+	hat.idleTimeout = d
+}
+
+func (hat *PanTiltHat) getIdleTimeout() time.Duration {
+	hat.idleMu.Lock()
+	defer hat.idleMu.Unlock()
+	return hat.idleTimeout
+}
+
+func (hat *PanTiltHat) runIdleMonitor() {
+	defer close(hat.idleDone)
+
+	ticker := time.NewTicker(idlePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-hat.idleQuit:
+			return
+		case <-ticker.C:
+			hat.disableIdleServos()
+		}
+	}
+}
+
+func (hat *PanTiltHat) disableIdleServos() {
+	timeout := hat.getIdleTimeout()
+	if timeout <= 0 {
+		return
+	}
+
+	now := time.Now()
+	for _, s := range hat.Servos {
+		if s.idleSince(now) >= timeout {
+			if err := s.Disable(); err != nil {
+				hat.log.Errorf("idle-disabling servo %d: %v", s.index, err)
+			}
+		}
+	}
+}
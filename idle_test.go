@@ -0,0 +1,54 @@
+package pantilthat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIdleTimeoutDisablesServo(t *testing.T) {
+	hat, bus := newTestHat(func(p *PanTiltHatParams) { p.IdleTimeout = 1 })
+	hat.SetIdleTimeout(30 * time.Millisecond)
+
+	if err := hat.SetServoOne(10); err != nil {
+		t.Fatalf("SetServoOne: %v", err)
+	}
+	if bus.reg(reg_config)&(1<<config_servo1_bit) == 0 {
+		t.Fatalf("servo 1 enable bit not set after SetServoOne")
+	}
+
+	time.Sleep(idlePollInterval*2 + 50*time.Millisecond)
+
+	if bus.reg(reg_config)&(1<<config_servo1_bit) != 0 {
+		t.Errorf("servo 1 enable bit still set after idle timeout elapsed")
+	}
+}
+
+func TestSetIdleTimeoutZeroDisablesIdleHandling(t *testing.T) {
+	hat, bus := newTestHat(func(p *PanTiltHatParams) { p.IdleTimeout = 1 })
+	hat.SetIdleTimeout(0)
+
+	if err := hat.SetServoOne(10); err != nil {
+		t.Fatalf("SetServoOne: %v", err)
+	}
+
+	time.Sleep(idlePollInterval*2 + 50*time.Millisecond)
+
+	if bus.reg(reg_config)&(1<<config_servo1_bit) == 0 {
+		t.Errorf("servo 1 was disabled even though idle handling was turned off")
+	}
+}
+
+func TestCloseShutsDownIdleMonitor(t *testing.T) {
+	hat, _ := newTestHat(nil)
+	done := make(chan struct{})
+	go func() {
+		hat.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Close did not return; idle monitor goroutine likely stuck")
+	}
+}
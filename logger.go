@@ -0,0 +1,26 @@
+package pantilthat
+
+// Logger is the minimal logging interface PanTiltHat needs. It matches
+// log.Logger's Printf signature so slog, zap, glog, etc. can all be
+// adapted to it with a one-line shim.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Printf(format string, v ...interface{}) {}
+
+// leveledLogger adds Debugf/Errorf helpers over a plain Logger so call
+// sites don't have to prefix every message by hand.
+type leveledLogger struct {
+	Logger
+}
+
+func (l leveledLogger) Debugf(format string, v ...interface{}) {
+	l.Printf("DEBUG: "+format, v...)
+}
+
+func (l leveledLogger) Errorf(format string, v ...interface{}) {
+	l.Printf("ERROR: "+format, v...)
+}
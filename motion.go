@@ -0,0 +1,182 @@
+package pantilthat
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// motionSamplePeriod matches the servo's ~50 Hz PWM refresh rate.
+const motionSamplePeriod = 20 * time.Millisecond
+
+// EasingFunc maps a fraction of motion elapsed, t in [0, 1], to a fraction
+// of distance travelled, also in [0, 1].
+type EasingFunc func(t float64) float64
+
+// Linear applies no easing.
+func Linear(t float64) float64 {
+	return t
+}
+
+// EaseInOutCubic accelerates into and decelerates out of the motion.
+func EaseInOutCubic(t float64) float64 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	return 1 - math.Pow(-2*t+2, 3)/2
+}
+
+// Quintic is a steeper S-curve than EaseInOutCubic, holding still longer at
+// each end of the motion.
+func Quintic(t float64) float64 {
+	if t < 0.5 {
+		return 16 * t * t * t * t * t
+	}
+	return 1 - math.Pow(-2*t+2, 5)/2
+}
+
+type motion struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// MoveServo moves servo index smoothly to targetDeg at speedDegPerSec,
+// shaped by easing, blocking until the motion completes or is cancelled.
+func (hat *PanTiltHat) MoveServo(index uint8, targetDeg int16, speedDegPerSec float64, easing EasingFunc) error {
+	errCh, cancel := hat.MoveServoAsync(index, targetDeg, speedDegPerSec, easing)
+	defer cancel()
+	return <-errCh
+}
+
+// MoveServoAsync is the non-blocking form of MoveServo. It returns
+// immediately with a channel that receives the motion's result, and a
+// CancelFunc that aborts the motion early.
+func (hat *PanTiltHat) MoveServoAsync(index uint8, targetDeg int16, speedDegPerSec float64, easing EasingFunc) (<-chan error, context.CancelFunc) {
+	errCh := make(chan error, 1)
+
+	s, err := hat.servoByIndex(index)
+	if err != nil {
+		errCh <- err
+		close(errCh)
+		return errCh, func() {}
+	}
+	if easing == nil {
+		easing = Linear
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.motion.mu.Lock()
+	if s.motion.cancel != nil {
+		s.motion.cancel()
+	}
+	s.motion.cancel = cancel
+	s.motion.mu.Unlock()
+
+	go func() {
+		err := s.runMotion(ctx, targetDeg, speedDegPerSec, easing)
+		errCh <- err
+		close(errCh)
+	}()
+
+	return errCh, cancel
+}
+
+func (s *Servo) runMotion(ctx context.Context, targetDeg int16, speedDegPerSec float64, easing EasingFunc) error {
+	start, err := s.GetAngle()
+	if err != nil {
+		return err
+	}
+
+	distance := math.Abs(float64(targetDeg) - float64(start))
+	if distance == 0 || speedDegPerSec <= 0 {
+		return s.SetAngle(targetDeg)
+	}
+	duration := time.Duration(distance / speedDegPerSec * float64(time.Second))
+
+	ticker := time.NewTicker(motionSamplePeriod)
+	defer ticker.Stop()
+
+	begin := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			t := float64(now.Sub(begin)) / float64(duration)
+			if t >= 1 {
+				return s.SetAngle(targetDeg)
+			}
+			eased := easing(t)
+			angle := float64(start) + (float64(targetDeg)-float64(start))*eased
+			if err := s.SetAngle(int16(math.Round(angle))); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// MoveBoth moves both servos to pan and tilt simultaneously, speed-matched
+// so they arrive together after duration regardless of travel distance.
+// Camera-tracking callers use this to avoid one axis settling before the
+// other.
+func (hat *PanTiltHat) MoveBoth(pan, tilt int16, duration time.Duration) error {
+	panErr, panCancel := hat.moveAxisOver(1, pan, duration)
+	tiltErr, tiltCancel := hat.moveAxisOver(2, tilt, duration)
+	defer panCancel()
+	defer tiltCancel()
+
+	err1 := <-panErr
+	err2 := <-tiltErr
+	if err1 != nil {
+		return err1
+	}
+	return err2
+}
+
+func (hat *PanTiltHat) moveAxisOver(index uint8, targetDeg int16, duration time.Duration) (<-chan error, context.CancelFunc) {
+	s, err := hat.servoByIndex(index)
+	if err != nil {
+		errCh := make(chan error, 1)
+		errCh <- err
+		close(errCh)
+		return errCh, func() {}
+	}
+
+	current, err := s.GetAngle()
+	if err != nil {
+		errCh := make(chan error, 1)
+		errCh <- err
+		close(errCh)
+		return errCh, func() {}
+	}
+
+	distance := math.Abs(float64(targetDeg) - float64(current))
+	if distance == 0 || duration <= 0 {
+		errCh := make(chan error, 1)
+		errCh <- s.SetAngle(targetDeg)
+		close(errCh)
+		return errCh, func() {}
+	}
+
+	speed := distance / duration.Seconds()
+	return hat.MoveServoAsync(index, targetDeg, speed, EaseInOutCubic)
+}
+
+// Stop cancels any in-flight MoveServo/MoveServoAsync motion on servo index
+// and returns its current angle, re-read from the HAT.
+func (hat *PanTiltHat) Stop(index uint8) (int8, error) {
+	s, err := hat.servoByIndex(index)
+	if err != nil {
+		return 0, err
+	}
+
+	s.motion.mu.Lock()
+	if s.motion.cancel != nil {
+		s.motion.cancel()
+		s.motion.cancel = nil
+	}
+	s.motion.mu.Unlock()
+
+	return s.GetAngle()
+}
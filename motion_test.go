@@ -0,0 +1,91 @@
+package pantilthat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEasingFuncsStartAndEndAtBounds(t *testing.T) {
+	for _, easing := range []struct {
+		name string
+		fn   EasingFunc
+	}{
+		{"Linear", Linear},
+		{"EaseInOutCubic", EaseInOutCubic},
+		{"Quintic", Quintic},
+	} {
+		if got := easing.fn(0); got != 0 {
+			t.Errorf("%s(0) = %v, want 0", easing.name, got)
+		}
+		if got := easing.fn(1); got != 1 {
+			t.Errorf("%s(1) = %v, want 1", easing.name, got)
+		}
+	}
+}
+
+// TestMoveServoTakesMultipleSteps guards against the motion duration being
+// truncated to whole seconds before it's used: a short, fast move (well
+// under one second) must still be sampled several times rather than
+// jumping straight to the target in a single write.
+func TestMoveServoTakesMultipleSteps(t *testing.T) {
+	hat, bus := newTestHat(nil)
+
+	// 5 degrees at 50 degrees/sec is a 100ms move, sampled every 20ms.
+	if err := hat.MoveServo(1, 5, 50, Linear); err != nil {
+		t.Fatalf("MoveServo: %v", err)
+	}
+
+	got, err := hat.GetServoOne()
+	if err != nil {
+		t.Fatalf("GetServoOne: %v", err)
+	}
+	if got != 5 {
+		t.Errorf("final angle = %d, want 5", got)
+	}
+
+	if n := bus.writeWordCallCount(); n < 2 {
+		t.Errorf("writeWordCalls = %d, want >1 for a smooth move (duration may have truncated to 0)", n)
+	}
+}
+
+func TestMoveBothFinishesBothAxes(t *testing.T) {
+	hat, _ := newTestHat(nil)
+
+	if err := hat.MoveBoth(10, -10, 100*time.Millisecond); err != nil {
+		t.Fatalf("MoveBoth: %v", err)
+	}
+
+	pan, err := hat.GetServoOne()
+	if err != nil {
+		t.Fatalf("GetServoOne: %v", err)
+	}
+	if pan != 10 {
+		t.Errorf("pan = %d, want 10", pan)
+	}
+
+	tilt, err := hat.GetServoTwo()
+	if err != nil {
+		t.Fatalf("GetServoTwo: %v", err)
+	}
+	if tilt != -10 {
+		t.Errorf("tilt = %d, want -10", tilt)
+	}
+}
+
+func TestStopHaltsAnInFlightMotion(t *testing.T) {
+	hat, bus := newTestHat(nil)
+
+	_, cancel := hat.MoveServoAsync(1, 80, 5, Linear) // 16s move; nowhere near done
+	defer cancel()
+	time.Sleep(50 * time.Millisecond)
+
+	if _, err := hat.Stop(1); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	afterStop := bus.writeWordCallCount()
+	time.Sleep(3 * motionSamplePeriod)
+	if n := bus.writeWordCallCount(); n != afterStop {
+		t.Errorf("writes kept happening after Stop: %d -> %d", afterStop, n)
+	}
+}
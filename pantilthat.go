@@ -2,41 +2,74 @@ package pantilthat
 
 import "fmt"
 import "github.com/go-daq/smbus"
-import "log"
 import "math"
-
-/*
-Caveats:
-- LEDs are not currently supported.
-*/
+import "sync"
+import "time"
 
 type PanTiltHatParams struct {
 	Servo1Min, Servo1Max, Servo2Min, Servo2Max uint16
 	IdleTimeout                                int
 	Address                                    uint8
+
+	// Bus, if set, is used in place of opening github.com/go-daq/smbus
+	// directly, letting callers supply a fake for tests or a backend for
+	// a board where the HAT isn't on the default bus.
+	Bus I2CBus
+	// BusNumber selects the /dev/i2c-N device to open when Bus is nil.
+	// Defaults to 1, the Raspberry Pi's user-facing bus.
+	BusNumber int
+
+	// Logger receives diagnostic output; defaults to a no-op so library
+	// use doesn't flood stdout. Plug in slog/zap/glog by adapting it to
+	// this interface.
+	Logger Logger
 }
 
 type PanTiltHat struct {
 	params PanTiltHatParams
 
-	// "zero" values are good defaults for these.
-	servo1Timeout, servo2Timeout int
-	enableServo1, enableServo2   bool
-
-	// i2c is special
+	log leveledLogger
+
+	Servos [2]*Servo
+
+	// Idle handling, see idle.go.
+	idleMu      sync.Mutex
+	idleTimeout time.Duration
+	idleQuit    chan struct{}
+	idleDone    chan struct{}
+
+	// LED state, see ws2812.go. pixelsMu guards lightMode, lightsOn,
+	// pixels and brightness, which are read and written both by callers
+	// and by the Rainbow animation goroutine.
+	pixelsMu   sync.Mutex
+	lightMode  LightMode
+	lightsOn   bool
+	pixels     [numPixels]rgbwPixel
+	brightness float64
+	rainbow    ledAnim
+
+	// i2c is special. i2cRetryMu guards i2cRetries/i2cRetryTime, which
+	// SetI2CRetries can change while a write from another goroutine is
+	// already inside withRetry.
+	i2cRetryMu   sync.Mutex
 	i2cRetries   int
-	i2cRetryTime float64
+	i2cRetryTime time.Duration
 	i2cAddress   uint8
-	i2cBus       *smbus.Conn
+	i2cBus       I2CBus
 }
 
 const (
 	reg_config  = 0x00
 	reg_servo1  = 0x01
 	reg_servo2  = 0x03
-	reg_ws2812  = 0x05 // unused.
+	reg_ws2812  = 0x05
 	reg_update  = 0x4E
 	update_wait = 0.03
+
+	config_servo1_bit = 0
+	config_servo2_bit = 1
+	config_lights_bit = 2
+	config_mode_bit   = 3
 )
 
 func MakePanTiltHat(params *PanTiltHatParams) (*PanTiltHat, error) {
@@ -60,24 +93,47 @@ func MakePanTiltHat(params *PanTiltHatParams) (*PanTiltHat, error) {
 	if params.Address == 0 {
 		params.Address = 0x15
 	}
+	if params.BusNumber == 0 {
+		params.BusNumber = 1
+	}
+	if params.Logger == nil {
+		params.Logger = noopLogger{}
+	}
 
 	hat.params = *params
+	hat.log = leveledLogger{params.Logger}
+	hat.brightness = 1.0
 
 	hat.i2cRetries = 10
-	hat.i2cRetryTime = 0.01
+	hat.i2cRetryTime = 10 * time.Millisecond
 	hat.i2cAddress = params.Address
-	i2cBus, err := smbus.Open(1, hat.i2cAddress)
-	if err != nil {
-		return nil, err
+
+	hat.i2cBus = params.Bus
+	if hat.i2cBus == nil {
+		conn, err := smbus.Open(params.BusNumber, hat.i2cAddress)
+		if err != nil {
+			return nil, err
+		}
+		hat.i2cBus = &smbusBus{conn: conn}
 	}
-	hat.i2cBus = i2cBus
+
+	hat.Servos[0] = &Servo{hat: hat, index: 1, reg: reg_servo1, min: params.Servo1Min, max: params.Servo1Max}
+	hat.Servos[1] = &Servo{hat: hat, index: 2, reg: reg_servo2, min: params.Servo2Min, max: params.Servo2Max}
+
+	hat.idleTimeout = time.Duration(params.IdleTimeout) * time.Second
+	hat.idleQuit = make(chan struct{})
+	hat.idleDone = make(chan struct{})
+	go hat.runIdleMonitor()
 
 	return hat, nil
 }
 
 func (hat *PanTiltHat) Close() {
-	hat.enableServo1 = false
-	hat.enableServo2 = false
+	close(hat.idleQuit)
+	<-hat.idleDone
+
+	hat.Servos[0].setEnabled(false)
+	hat.Servos[1].setEnabled(false)
 	hat.setConfig()
 }
 
@@ -85,64 +141,69 @@ func (hat *PanTiltHat) setConfig() error {
 	var config uint8
 
 	var enableServo1Bit, enableServo2Bit uint8
-	if hat.enableServo1 {
+	if hat.Servos[0].isEnabled() {
 		enableServo1Bit = 1
 	}
-	if hat.enableServo2 {
+	if hat.Servos[1].isEnabled() {
 		enableServo2Bit = 1
 	}
-	config |= enableServo1Bit
-	config |= enableServo2Bit << 1
-	// Rest of the bits are used for lights, leaving at 0.
-	err := hat.i2cBus.WriteReg(hat.i2cAddress, reg_config, config)
+	hat.pixelsMu.Lock()
+	lightsOn, lightMode := hat.lightsOn, hat.lightMode
+	hat.pixelsMu.Unlock()
 
-	return err
+	var lightsOnBit, lightModeBit uint8
+	if lightsOn {
+		lightsOnBit = 1
+	}
+	if lightMode == LightModeSK6812 {
+		lightModeBit = 1
+	}
+
+	config |= enableServo1Bit << config_servo1_bit
+	config |= enableServo2Bit << config_servo2_bit
+	config |= lightsOnBit << config_lights_bit
+	config |= lightModeBit << config_mode_bit
+	return hat.withRetry(func() error {
+		return hat.i2cBus.WriteReg(hat.i2cAddress, reg_config, config)
+	})
 }
 
-func (hat *PanTiltHat) ServoEnable(index uint8, state bool) error {
+func (hat *PanTiltHat) servoByIndex(index uint8) (*Servo, error) {
 	if index < 1 || index > 2 {
-		return fmt.Errorf("Servo index out of range: %d", index)
-	}
-
-	if index == 1 {
-		hat.enableServo1 = state
-	} else if index == 2 {
-		hat.enableServo2 = state
+		return nil, fmt.Errorf("Servo index out of range: %d", index)
 	}
+	return hat.Servos[index-1], nil
+}
 
-	err := hat.setConfig()
+func (hat *PanTiltHat) ServoEnable(index uint8, state bool) error {
+	s, err := hat.servoByIndex(index)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	if state {
+		return s.Enable()
+	}
+	return s.Disable()
 }
 
 func (hat *PanTiltHat) ServoPulseMin(index uint8, value uint16) error {
-	if index < 1 || index > 2 {
-		return fmt.Errorf("Servo index out of range: %d", index)
-	}
-
-	if index == 1 {
-		hat.params.Servo1Min = value
-	} else if index == 2 {
-		hat.params.Servo2Min = value
+	s, err := hat.servoByIndex(index)
+	if err != nil {
+		return err
 	}
 
+	s.min = value
 	return nil
 }
 
 func (hat *PanTiltHat) ServoPulseMax(index uint8, value uint16) error {
-	if index < 1 || index > 2 {
-		return fmt.Errorf("Servo index out of range: %d", index)
-	}
-
-	if index == 1 {
-		hat.params.Servo1Max = value
-	} else if index == 2 {
-		hat.params.Servo2Max = value
+	s, err := hat.servoByIndex(index)
+	if err != nil {
+		return err
 	}
 
+	s.max = value
 	return nil
 }
 
@@ -159,73 +220,29 @@ func servoUsToDegrees(us, usMin, usMax uint16) (int8, error) {
 
 func servoDegreesToUs(degree int16, usMin, usMax uint16) (uint16, error) {
 	if degree < -90 || degree > 90 {
-		return 0, fmt.Errorf("Degree outside range: %i", degree)
+		return 0, fmt.Errorf("Degree outside range: %d", degree)
 	}
 
 	degree += 90
 	usRange := usMax - usMin
 	us := (float64(usRange) / 180.0) * float64(degree)
-	log.Printf("degree %d yielded us %f\n", degree, us)
 	return usMin + uint16(us), nil
 }
 
 func (hat *PanTiltHat) GetServoOne() (int8, error) {
-	value, err := hat.i2cBus.ReadWord(hat.i2cAddress, reg_servo1)
-	if err != nil {
-		return 0, err
-	}
-
-	// Convert pulse time in microseconds into degrees:
-
-	return servoUsToDegrees(value, hat.params.Servo1Min, hat.params.Servo1Max)
+	return hat.Servos[0].GetAngle()
 }
 
 func (hat *PanTiltHat) GetServoTwo() (int8, error) {
-	value, err := hat.i2cBus.ReadWord(hat.i2cAddress, reg_servo2)
-	if err != nil {
-		return 0, err
-	}
-
-	// Convert pulse time in microseconds into degrees:
-	return servoUsToDegrees(value, hat.params.Servo2Min, hat.params.Servo2Max)
+	return hat.Servos[1].GetAngle()
 }
 
 func (hat *PanTiltHat) SetServoOne(angle int16) error {
-	if !hat.enableServo1 {
-		err := hat.ServoEnable(1, true)
-		if err != nil {
-			return err
-		}
-	}
-
-	us, err := servoDegreesToUs(angle, hat.params.Servo1Min, hat.params.Servo1Max)
-	if err != nil {
-		return err
-	}
-
-	log.Printf("Writing %d to servo 1\n", us)
-	err = hat.i2cBus.WriteWord(hat.i2cAddress, reg_servo1, us)
-	// Consider idle handling here.
-	return err
+	return hat.Servos[0].SetAngle(angle)
 }
 
 func (hat *PanTiltHat) SetServoTwo(angle int16) error {
-	if !hat.enableServo2 {
-		err := hat.ServoEnable(2, true)
-		if err != nil {
-			return err
-		}
-	}
-
-	us, err := servoDegreesToUs(angle, hat.params.Servo2Min, hat.params.Servo2Max)
-	if err != nil {
-		return err
-	}
-
-	log.Printf("Writing %d to servo 2\n", us)
-	err = hat.i2cBus.WriteWord(hat.i2cAddress, reg_servo2, us)
-	// Consider idle handling here.
-	return err
+	return hat.Servos[1].SetAngle(angle)
 }
 
 func (hat *PanTiltHat) Pan(angle int16) error {
@@ -0,0 +1,113 @@
+package pantilthat
+
+import (
+	"sync"
+	"time"
+)
+
+// Servo drives a single servo channel on the HAT. PanTiltHat.Pan, Tilt and
+// the legacy SetServoOne/SetServoTwo/GetServoOne/GetServoTwo methods are
+// thin wrappers over hat.Servos[0] and hat.Servos[1].
+type Servo struct {
+	hat   *PanTiltHat
+	index uint8 // 1 or 2, for log and error messages.
+	reg   uint8
+	min   uint16
+	max   uint16
+
+	motion motion
+
+	// stateMu guards enabled and lastActive, both of which are read and
+	// written from whatever goroutine the caller is on as well as from
+	// the idle monitor goroutine (idle.go).
+	stateMu    sync.Mutex
+	enabled    bool
+	lastActive time.Time
+}
+
+// isEnabled reports whether the servo currently has PWM output enabled.
+func (s *Servo) isEnabled() bool {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	return s.enabled
+}
+
+func (s *Servo) setEnabled(state bool) {
+	s.stateMu.Lock()
+	s.enabled = state
+	s.stateMu.Unlock()
+}
+
+// idleSince reports how long it has been since the servo was last written
+// to, or zero if it isn't enabled or has never been written to.
+func (s *Servo) idleSince(now time.Time) time.Duration {
+	s.stateMu.Lock()
+	enabled := s.enabled
+	last := s.lastActive
+	s.stateMu.Unlock()
+
+	if !enabled || last.IsZero() {
+		return 0
+	}
+	return now.Sub(last)
+}
+
+// Enable powers the servo's PWM output.
+func (s *Servo) Enable() error {
+	s.setEnabled(true)
+	return s.hat.setConfig()
+}
+
+// Disable cuts the servo's PWM output.
+func (s *Servo) Disable() error {
+	s.setEnabled(false)
+	return s.hat.setConfig()
+}
+
+// PulseRange sets the microsecond pulse width corresponding to -90 and +90
+// degrees.
+func (s *Servo) PulseRange(min, max uint16) {
+	s.min = min
+	s.max = max
+}
+
+// GetAngle reads the servo's last-written pulse width back off the HAT and
+// converts it to degrees.
+func (s *Servo) GetAngle() (int8, error) {
+	var value uint16
+	err := s.hat.withRetry(func() error {
+		var err error
+		value, err = s.hat.i2cBus.ReadWord(s.hat.i2cAddress, s.reg)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return servoUsToDegrees(value, s.min, s.max)
+}
+
+// SetAngle moves the servo to angle degrees (-90 to +90), enabling it first
+// if necessary.
+func (s *Servo) SetAngle(angle int16) error {
+	if !s.isEnabled() {
+		if err := s.Enable(); err != nil {
+			return err
+		}
+	}
+
+	us, err := servoDegreesToUs(angle, s.min, s.max)
+	if err != nil {
+		return err
+	}
+
+	s.hat.log.Debugf("writing %d to servo %d", us, s.index)
+	err = s.hat.withRetry(func() error {
+		return s.hat.i2cBus.WriteWord(s.hat.i2cAddress, s.reg, us)
+	})
+	if err == nil {
+		s.stateMu.Lock()
+		s.lastActive = time.Now()
+		s.stateMu.Unlock()
+	}
+	return err
+}
@@ -0,0 +1,295 @@
+package pantilthat
+
+import (
+	"context"
+	"fmt"
+	"image/color"
+	"math"
+	"sync"
+	"time"
+)
+
+// LightMode selects which LED variant is wired to the HAT's light rail.
+// This drives config bits 2-3: bit 2 enables the light rail at all, bit 3
+// picks the pixel format sent to it.
+type LightMode uint8
+
+const (
+	// LightModeWS2812 drives 24-bit GRB pixels (no white channel).
+	LightModeWS2812 LightMode = iota
+	// LightModeSK6812 drives 32-bit GRBW pixels. The register window
+	// only has room for maxRGBWPixels of these; Show silently drops the
+	// rest of the 24-pixel buffer rather than writing past reg_update.
+	LightModeSK6812
+)
+
+const (
+	numPixels = 24
+	// Typical i2c-dev ioctl()s cap a single SMBus block transfer at 32
+	// bytes, so bursts larger than that are split into multiple writes.
+	maxI2CChunkBytes = 32
+)
+
+// ws2812WindowBytes is the register span the HAT actually gives the light
+// rail: the 73 registers from reg_ws2812 up to, but not including,
+// reg_update. 24 WS2812 pixels at 3 bytes each use 72 of them; the SK6812
+// RGBW format needs 4 bytes per pixel, so only maxRGBWPixels (18) of the
+// 24-pixel buffer fit before colliding with reg_update.
+const ws2812WindowBytes = reg_update - reg_ws2812
+
+// maxRGBWPixels is how many SK6812 pixels fit in ws2812WindowBytes.
+const maxRGBWPixels = ws2812WindowBytes / 4
+
+type rgbwPixel struct {
+	r, g, b, w uint8
+}
+
+// ledAnim tracks a single in-flight background LED animation (Rainbow).
+// Like motion.go's per-servo motion it cancels the previous run, but stop
+// also waits for that run's goroutine to exit.
+type ledAnim struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// stop cancels any running animation and waits for its goroutine to exit
+// before returning, so a new animation never races the old one over
+// hat.pixels.
+func (a *ledAnim) stop() {
+	a.mu.Lock()
+	cancel, done := a.cancel, a.done
+	a.cancel, a.done = nil, nil
+	a.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+func (a *ledAnim) start(cancel context.CancelFunc, done chan struct{}) {
+	a.mu.Lock()
+	a.cancel, a.done = cancel, done
+	a.mu.Unlock()
+}
+
+// SetLightMode selects the LED variant attached to the light rail and
+// enables it. Pixel data already buffered via SetPixel/SetPixelRGBW/SetAll
+// is preserved and will be sent on the next Show.
+func (hat *PanTiltHat) SetLightMode(mode LightMode) error {
+	hat.pixelsMu.Lock()
+	hat.lightMode = mode
+	hat.lightsOn = true
+	hat.pixelsMu.Unlock()
+	return hat.setConfig()
+}
+
+// SetPixel sets the RGB channels of pixel i (0-23), leaving its white
+// channel, if any, untouched. Call Show to flush the buffer to the HAT.
+func (hat *PanTiltHat) SetPixel(i int, r, g, b uint8) error {
+	if i < 0 || i >= numPixels {
+		return fmt.Errorf("pixel index out of range: %d", i)
+	}
+	hat.pixelsMu.Lock()
+	hat.pixels[i].r = r
+	hat.pixels[i].g = g
+	hat.pixels[i].b = b
+	hat.pixelsMu.Unlock()
+	return nil
+}
+
+// SetPixelRGBW sets all four channels of pixel i (0-23), for use with the
+// SK6812 RGBW variant. Call Show to flush the buffer to the HAT.
+func (hat *PanTiltHat) SetPixelRGBW(i int, r, g, b, w uint8) error {
+	if i < 0 || i >= numPixels {
+		return fmt.Errorf("pixel index out of range: %d", i)
+	}
+	hat.pixelsMu.Lock()
+	hat.pixels[i] = rgbwPixel{r, g, b, w}
+	hat.pixelsMu.Unlock()
+	return nil
+}
+
+// SetAll sets the RGB channels of every pixel, leaving white channels
+// untouched. Call Show to flush the buffer to the HAT.
+func (hat *PanTiltHat) SetAll(r, g, b uint8) error {
+	hat.pixelsMu.Lock()
+	for i := range hat.pixels {
+		hat.pixels[i].r = r
+		hat.pixels[i].g = g
+		hat.pixels[i].b = b
+	}
+	hat.pixelsMu.Unlock()
+	return nil
+}
+
+// Clear zeroes the pixel buffer. Call Show to flush the change to the HAT.
+func (hat *PanTiltHat) Clear() error {
+	hat.pixelsMu.Lock()
+	for i := range hat.pixels {
+		hat.pixels[i] = rgbwPixel{}
+	}
+	hat.pixelsMu.Unlock()
+	return nil
+}
+
+// SetBrightness scales every channel by fraction (0.0-1.0) before it is
+// sent to the HAT in Show. It does not alter the buffered pixel values, so
+// it can be adjusted without re-specifying colors.
+func (hat *PanTiltHat) SetBrightness(fraction float64) error {
+	if fraction < 0.0 || fraction > 1.0 {
+		return fmt.Errorf("brightness out of range: %f", fraction)
+	}
+	hat.pixelsMu.Lock()
+	hat.brightness = fraction
+	hat.pixelsMu.Unlock()
+	return nil
+}
+
+// Show flushes the buffered pixels to the HAT, pre-multiplying each channel
+// by the configured brightness, chunking the write to fit typical i2c-dev
+// block transfer limits.
+func (hat *PanTiltHat) Show() error {
+	hat.pixelsMu.Lock()
+	pixels := hat.pixels
+	brightness := hat.brightness
+	lightMode := hat.lightMode
+	hat.pixelsMu.Unlock()
+
+	pixelCount := numPixels
+	if lightMode == LightModeSK6812 && pixelCount > maxRGBWPixels {
+		// The full 24-pixel buffer doesn't fit in the RGBW register
+		// window; send as many as do and leave the rest unset rather
+		// than writing past reg_update.
+		pixelCount = maxRGBWPixels
+	}
+
+	data := make([]byte, 0, pixelCount*4)
+	for _, p := range pixels[:pixelCount] {
+		data = append(data, scaleChannel(p.g, brightness), scaleChannel(p.r, brightness), scaleChannel(p.b, brightness))
+		if lightMode == LightModeSK6812 {
+			data = append(data, scaleChannel(p.w, brightness))
+		}
+	}
+
+	for offset := 0; offset < len(data); offset += maxI2CChunkBytes {
+		end := offset + maxI2CChunkBytes
+		if end > len(data) {
+			end = len(data)
+		}
+		chunk := data[offset:end]
+		startReg := reg_ws2812 + uint8(offset)
+		err := hat.withRetry(func() error {
+			return hat.i2cBus.WriteBlock(hat.i2cAddress, startReg, chunk)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return hat.withRetry(func() error {
+		return hat.i2cBus.WriteReg(hat.i2cAddress, reg_update, 1)
+	})
+}
+
+func scaleChannel(v uint8, fraction float64) byte {
+	return byte(float64(v) * fraction)
+}
+
+// Fade smoothly transitions every pixel from `from` to `to` over duration,
+// calling Show at each step. It blocks until the fade completes.
+func (hat *PanTiltHat) Fade(from, to color.RGBA, duration time.Duration) error {
+	const steps = 50
+	stepDuration := duration / steps
+
+	for s := 0; s <= steps; s++ {
+		t := float64(s) / float64(steps)
+		r := lerp(from.R, to.R, t)
+		g := lerp(from.G, to.G, t)
+		b := lerp(from.B, to.B, t)
+		if err := hat.SetAll(r, g, b); err != nil {
+			return err
+		}
+		if err := hat.Show(); err != nil {
+			return err
+		}
+		if s < steps {
+			time.Sleep(stepDuration)
+		}
+	}
+
+	return nil
+}
+
+func lerp(from, to uint8, t float64) uint8 {
+	return uint8(float64(from) + (float64(to)-float64(from))*t)
+}
+
+// Rainbow starts a background animation cycling all pixels through the hue
+// wheel once per period, flushing with Show on every step. Calling Rainbow
+// again cancels and joins any previously running animation before the new
+// one touches the pixel buffer. It runs until the returned CancelFunc is
+// called or ctx is cancelled; the CancelFunc returns without waiting for
+// the animation goroutine to exit.
+func (hat *PanTiltHat) Rainbow(ctx context.Context, period time.Duration) context.CancelFunc {
+	hat.rainbow.stop()
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		const steps = 360
+		stepDuration := period / steps
+		ticker := time.NewTicker(stepDuration)
+		defer ticker.Stop()
+
+		hue := 0
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r, g, b := hueToRGB(hue)
+				hat.SetAll(r, g, b)
+				hat.Show()
+				hue = (hue + 1) % steps
+			}
+		}
+	}()
+
+	hat.rainbow.start(cancel, done)
+	return cancel
+}
+
+// hueToRGB converts a hue in [0, 360) to fully saturated, full value RGB.
+func hueToRGB(hue int) (uint8, uint8, uint8) {
+	h := float64(hue%360) / 60.0
+	x := 1 - math.Abs(mod2(h)-1)
+
+	var r, g, b float64
+	switch {
+	case h < 1:
+		r, g, b = 1, x, 0
+	case h < 2:
+		r, g, b = x, 1, 0
+	case h < 3:
+		r, g, b = 0, 1, x
+	case h < 4:
+		r, g, b = 0, x, 1
+	case h < 5:
+		r, g, b = x, 0, 1
+	default:
+		r, g, b = 1, 0, x
+	}
+
+	return uint8(r * 255), uint8(g * 255), uint8(b * 255)
+}
+
+func mod2(v float64) float64 {
+	for v >= 2 {
+		v -= 2
+	}
+	return v
+}
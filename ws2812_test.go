@@ -0,0 +1,176 @@
+package pantilthat
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSetPixelAndShowWritesGRBOrder(t *testing.T) {
+	hat, bus := newTestHat(nil)
+
+	if err := hat.SetPixel(0, 0x11, 0x22, 0x33); err != nil {
+		t.Fatalf("SetPixel: %v", err)
+	}
+	if err := hat.Show(); err != nil {
+		t.Fatalf("Show: %v", err)
+	}
+
+	if got, want := bus.reg(reg_ws2812+0), uint8(0x22); got != want {
+		t.Errorf("reg[0] (G) = %#x, want %#x", got, want)
+	}
+	if got, want := bus.reg(reg_ws2812+1), uint8(0x11); got != want {
+		t.Errorf("reg[1] (R) = %#x, want %#x", got, want)
+	}
+	if got, want := bus.reg(reg_ws2812+2), uint8(0x33); got != want {
+		t.Errorf("reg[2] (B) = %#x, want %#x", got, want)
+	}
+	if bus.reg(reg_update) != 1 {
+		t.Errorf("reg_update not set after Show")
+	}
+}
+
+func TestSetAllSetsEveryPixel(t *testing.T) {
+	hat, bus := newTestHat(nil)
+
+	if err := hat.SetAll(0x10, 0x20, 0x30); err != nil {
+		t.Fatalf("SetAll: %v", err)
+	}
+	if err := hat.Show(); err != nil {
+		t.Fatalf("Show: %v", err)
+	}
+
+	for i := 0; i < numPixels; i++ {
+		off := uint8(i * 3)
+		if got, want := bus.reg(reg_ws2812+off), uint8(0x20); got != want {
+			t.Errorf("pixel %d G = %#x, want %#x", i, got, want)
+		}
+		if got, want := bus.reg(reg_ws2812+off+1), uint8(0x10); got != want {
+			t.Errorf("pixel %d R = %#x, want %#x", i, got, want)
+		}
+		if got, want := bus.reg(reg_ws2812+off+2), uint8(0x30); got != want {
+			t.Errorf("pixel %d B = %#x, want %#x", i, got, want)
+		}
+	}
+}
+
+func TestShowChunksWritesOverMaxI2CChunkBytes(t *testing.T) {
+	hat, bus := newTestHat(nil)
+
+	if err := hat.SetAll(1, 2, 3); err != nil {
+		t.Fatalf("SetAll: %v", err)
+	}
+	if err := hat.Show(); err != nil {
+		t.Fatalf("Show: %v", err)
+	}
+
+	// 24 pixels * 3 bytes = 72 bytes, which exceeds maxI2CChunkBytes (32)
+	// and must be split across multiple WriteBlock calls; confirm the
+	// far end of the buffer landed correctly regardless of chunking.
+	lastOff := uint8((numPixels - 1) * 3)
+	if got, want := bus.reg(reg_ws2812+lastOff), uint8(2); got != want {
+		t.Errorf("last pixel G = %#x, want %#x", got, want)
+	}
+	if got, want := bus.reg(reg_ws2812+lastOff+1), uint8(1); got != want {
+		t.Errorf("last pixel R = %#x, want %#x", got, want)
+	}
+	if got, want := bus.reg(reg_ws2812+lastOff+2), uint8(3); got != want {
+		t.Errorf("last pixel B = %#x, want %#x", got, want)
+	}
+}
+
+func TestShowClipsSK6812ToRegisterWindow(t *testing.T) {
+	hat, bus := newTestHat(nil)
+
+	if err := hat.SetLightMode(LightModeSK6812); err != nil {
+		t.Fatalf("SetLightMode: %v", err)
+	}
+	for i := 0; i < numPixels; i++ {
+		if err := hat.SetPixelRGBW(i, 1, 2, 3, 4); err != nil {
+			t.Fatalf("SetPixelRGBW(%d): %v", i, err)
+		}
+	}
+	if err := hat.Show(); err != nil {
+		t.Fatalf("Show: %v", err)
+	}
+
+	lastInWindowOff := uint8((maxRGBWPixels - 1) * 4)
+	if got, want := bus.reg(reg_ws2812+lastInWindowOff), uint8(2); got != want {
+		t.Errorf("last in-window pixel G = %#x, want %#x", got, want)
+	}
+
+	// A full 24-pixel RGBW buffer would run past reg_update; Show must
+	// not have written anything at or beyond it.
+	pastWindowOff := uint8(maxRGBWPixels * 4)
+	if reg_ws2812+pastWindowOff >= reg_update {
+		t.Fatalf("test setup invalid: maxRGBWPixels already reaches reg_update")
+	}
+	if got := bus.reg(reg_ws2812 + pastWindowOff); got != 0 {
+		t.Errorf("byte past the RGBW window was written: %#x", got)
+	}
+}
+
+func TestSetBrightnessScalesChannels(t *testing.T) {
+	hat, bus := newTestHat(nil)
+
+	if err := hat.SetPixel(0, 200, 100, 50); err != nil {
+		t.Fatalf("SetPixel: %v", err)
+	}
+	if err := hat.SetBrightness(0.5); err != nil {
+		t.Fatalf("SetBrightness: %v", err)
+	}
+	if err := hat.Show(); err != nil {
+		t.Fatalf("Show: %v", err)
+	}
+
+	if got, want := bus.reg(reg_ws2812+0), scaleChannel(100, 0.5); got != want {
+		t.Errorf("G = %#x, want %#x", got, want)
+	}
+	if got, want := bus.reg(reg_ws2812+1), scaleChannel(200, 0.5); got != want {
+		t.Errorf("R = %#x, want %#x", got, want)
+	}
+	if got, want := bus.reg(reg_ws2812+2), scaleChannel(50, 0.5); got != want {
+		t.Errorf("B = %#x, want %#x", got, want)
+	}
+}
+
+func TestSetBrightnessRejectsOutOfRange(t *testing.T) {
+	hat, _ := newTestHat(nil)
+
+	if err := hat.SetBrightness(-0.1); err == nil {
+		t.Error("expected error for brightness below 0")
+	}
+	if err := hat.SetBrightness(1.1); err == nil {
+		t.Error("expected error for brightness above 1")
+	}
+}
+
+func TestRainbowCancelAndRestart(t *testing.T) {
+	hat, bus := newTestHat(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hat.Rainbow(ctx, 20*time.Millisecond)
+
+	time.Sleep(10 * time.Millisecond)
+	firstCalls := bus.writeBlockCallCount()
+
+	// Starting a second animation must cancel and join the first before
+	// touching the pixel buffer again.
+	cancel2 := hat.Rainbow(context.Background(), 20*time.Millisecond)
+	defer cancel2()
+
+	time.Sleep(10 * time.Millisecond)
+	if bus.writeBlockCallCount() < firstCalls {
+		t.Errorf("writeBlockCallCount went backwards after restart")
+	}
+
+	cancel()
+	cancel2()
+	hat.rainbow.stop()
+
+	afterStop := bus.writeBlockCallCount()
+	time.Sleep(30 * time.Millisecond)
+	if bus.writeBlockCallCount() != afterStop {
+		t.Errorf("Rainbow kept writing after being stopped")
+	}
+}